@@ -3,10 +3,13 @@ package main
 import (
 	"log"
 	"os"
+	"text/template"
 
 	"github.com/aybabtme/rgbterm"
 	"github.com/mattn/go-colorable"
 	"github.com/soldiermoth/humanlog"
+	"github.com/soldiermoth/humanlog/filter"
+	"github.com/soldiermoth/humanlog/human"
 	"github.com/urfave/cli"
 )
 
@@ -36,6 +39,8 @@ func newApp() *cli.App {
 
 	skip := cli.StringSlice{}
 	keep := cli.StringSlice{}
+	expandKeys := cli.StringSlice{}
+	var queryExpr string
 
 	skipFlag := cli.StringSliceFlag{
 		Name:  "skip",
@@ -81,6 +86,56 @@ func newApp() *cli.App {
 		Value: humanlog.DefaultOptions.TimeFormat,
 	}
 
+	format := cli.StringFlag{
+		Name:  "format",
+		Usage: "output format: one of terminal, json, logfmt or template (use --template with this one)",
+		Value: string(humanlog.FormatTerminal),
+	}
+
+	tmpl := cli.StringFlag{
+		Name:  "template",
+		Usage: `Go text/template used to render each entry when --format=template, e.g. "{{.Time}} {{.Level}} {{.Message}}"`,
+	}
+
+	verbosity := cli.StringFlag{
+		Name:  "verbosity",
+		Usage: "minimum level to show: trace, debug, info, warn, error or fatal",
+		Value: "trace",
+	}
+
+	vmodule := cli.StringFlag{
+		Name:  "vmodule",
+		Usage: `per-component verbosity overrides, e.g. "http/*=debug,db=warn"`,
+	}
+
+	vmoduleKey := cli.StringFlag{
+		Name:  "vmodule-key",
+		Usage: "field matched against --vmodule patterns",
+		Value: humanlog.DefaultOptions.VModuleKey,
+	}
+
+	query := cli.StringFlag{
+		Name:        "query, q",
+		Usage:       `predicate evaluated against each entry, e.g. level=="error" && status>=500 && msg~="timeout"`,
+		Destination: &queryExpr,
+	}
+
+	relativeTime := cli.BoolFlag{
+		Name:  "relative-time",
+		Usage: "render RFC3339 timestamp values as relative time, e.g. \"3m ago\"",
+	}
+
+	expand := cli.StringSliceFlag{
+		Name:  "expand",
+		Usage: "keys whose multi-line or object value is rendered as an indented block below the entry (default: stack, stacktrace, error)",
+		Value: &expandKeys,
+	}
+
+	collapseMultilineJSON := cli.BoolFlag{
+		Name:  "collapse-multiline-json",
+		Usage: "fold embedded newlines in string values into ⏎, for grep-friendly one-line-per-entry output (takes precedence over --expand)",
+	}
+
 	app := cli.NewApp()
 	app.Author = "Antoine Grondin"
 	app.Email = "antoine@digitalocean.com"
@@ -88,7 +143,7 @@ func newApp() *cli.App {
 	app.Version = version
 	app.Usage = "reads structured logs from stdin, makes them pretty on stdout!"
 
-	app.Flags = []cli.Flag{skipFlag, keepFlag, sortLongest, skipUnchanged, truncates, truncateLength, lightBg, timeFormat}
+	app.Flags = []cli.Flag{skipFlag, keepFlag, sortLongest, skipUnchanged, truncates, truncateLength, lightBg, timeFormat, format, tmpl, verbosity, vmodule, vmoduleKey, query, relativeTime, expand, collapseMultilineJSON}
 
 	app.Action = func(c *cli.Context) error {
 
@@ -109,6 +164,50 @@ func newApp() *cli.App {
 			opts.SetKeep(keep)
 		}
 
+		opts.Format = humanlog.OutputFormat(c.String(format.Name))
+		switch opts.Format {
+		case humanlog.FormatTerminal, humanlog.FormatJSON, humanlog.FormatLogfmt:
+			// nothing further to set up
+		case humanlog.FormatTemplate:
+			t, err := template.New("humanlog").Parse(c.String(tmpl.Name))
+			if err != nil {
+				fatalf(c, "invalid --template: %v", err)
+			}
+			opts.Template = t
+		default:
+			fatalf(c, "unknown --format %q", opts.Format)
+		}
+
+		opts.VModuleKey = c.String(vmoduleKey.Name)
+		if c.IsSet(verbosity.Name) || c.IsSet(vmodule.Name) {
+			threshold, ok := filter.ParseLevel(c.String(verbosity.Name))
+			if !ok {
+				fatalf(c, "unknown --verbosity %q", c.String(verbosity.Name))
+			}
+			f, err := filter.New(threshold, c.String(vmodule.Name))
+			if err != nil {
+				fatalf(c, "invalid --vmodule: %v", err)
+			}
+			opts.Filter = f
+		}
+
+		if c.IsSet("query") || c.IsSet("q") {
+			pred, err := humanlog.Compile(queryExpr)
+			if err != nil {
+				fatalf(c, "invalid --query: %v", err)
+			}
+			opts.Query = pred
+		}
+
+		if c.Bool(relativeTime.Name) {
+			opts.ValueFormatters = append(opts.ValueFormatters, human.RelativeTime())
+		}
+
+		if c.IsSet(expand.Name) {
+			opts.SetExpand(expandKeys)
+		}
+		opts.CollapseMultilineJSON = c.Bool(collapseMultilineJSON.Name)
+
 		log.Print("reading stdin...")
 		if err := humanlog.Scanner(os.Stdin, colorable.NewColorableStdout(), opts); err != nil {
 			log.Fatalf("scanning caught an error: %v", err)
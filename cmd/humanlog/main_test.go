@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soldiermoth/humanlog"
+)
+
+// withCleanOptions snapshots humanlog.DefaultOptions (which newApp's
+// Action mutates in place, since opts := humanlog.DefaultOptions shares
+// the same struct across runs) and restores it after the test, so tests
+// don't leak --query/--expand state into each other.
+func withCleanOptions(t *testing.T) {
+	t.Helper()
+	saved := *humanlog.DefaultOptions
+	t.Cleanup(func() { *humanlog.DefaultOptions = saved })
+}
+
+// runApp runs newApp() against args with stdin fed from in, capturing
+// whatever it writes to stdout.
+func runApp(t *testing.T, args []string, in string) string {
+	t.Helper()
+
+	origStdin, origStdout := os.Stdin, os.Stdout
+	defer func() { os.Stdin, os.Stdout = origStdin, origStdout }()
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(inW, in)
+		inW.Close()
+	}()
+	os.Stdin = inR
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = outW
+
+	var captured bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&captured, outR)
+		close(done)
+	}()
+
+	if err := newApp().Run(append([]string{"humanlog"}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	outW.Close()
+	<-done
+
+	return captured.String()
+}
+
+// TestNewAppQueryFlag exercises --query/-q end to end: this is the
+// entire chunk0-4 feature, and it silently did nothing because
+// cli.StringFlag{Name: "query, q"}.Name is "query, q", which IsSet never
+// matches.
+func TestNewAppQueryFlag(t *testing.T) {
+	in := `{"time":"2021-01-02T15:04:05Z","level":"info","msg":"ok","status":200}` + "\n" +
+		`{"time":"2021-01-02T15:04:06Z","level":"info","msg":"boom","status":503}` + "\n"
+
+	for _, flag := range []string{"--query", "-q"} {
+		t.Run(flag, func(t *testing.T) {
+			withCleanOptions(t)
+			out := runApp(t, []string{flag, `status>=500`, "--format=logfmt"}, in)
+			if strings.Contains(out, `msg=ok`) {
+				t.Errorf("%s status>=500 should have dropped the status=200 entry, got %q", flag, out)
+			}
+			if !strings.Contains(out, `msg=boom`) {
+				t.Errorf("%s status>=500 should have kept the status=503 entry, got %q", flag, out)
+			}
+		})
+	}
+}
+
+// TestNewAppExpandFlagReplacesDefaults makes sure --expand=<keys>
+// replaces the default expand key set (stack, stacktrace, error)
+// instead of appending to it, since the backing cli.StringSlice used to
+// be pre-seeded with the defaults and Set only appends.
+func TestNewAppExpandFlagReplacesDefaults(t *testing.T) {
+	withCleanOptions(t)
+
+	in := `{"time":"2021-01-02T15:04:05Z","level":"error","msg":"boom","stack":"a\nb","custom":"x\ny"}` + "\n"
+	out := runApp(t, []string{"--expand=custom"}, in)
+
+	if !strings.Contains(out, "custom:") {
+		t.Errorf("expected --expand=custom to expand the custom field, got %q", out)
+	}
+	if strings.Contains(out, "stack:") {
+		t.Errorf("--expand=custom should replace the default expand keys, not append to them; stack shouldn't expand, got %q", out)
+	}
+}
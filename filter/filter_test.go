@@ -0,0 +1,82 @@
+package filter
+
+import "testing"
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+		ok   bool
+	}{
+		{"trace", LevelTrace, true},
+		{"DEBUG", LevelDebug, true},
+		{"info", LevelInfo, true},
+		{"warn", LevelWarn, true},
+		{"warning", LevelWarn, true},
+		{"error", LevelError, true},
+		{"err", LevelError, true},
+		{"fatal", LevelFatal, true},
+		{"panic", LevelFatal, true},
+		{"nonsense", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLevel(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseVModule(t *testing.T) {
+	rules, err := ParseVModule("http/*=debug, db=warn")
+	if err != nil {
+		t.Fatalf("ParseVModule: %v", err)
+	}
+	want := []Rule{
+		{Pattern: "http/*", Level: LevelDebug},
+		{Pattern: "db", Level: LevelWarn},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(rules), len(want))
+	}
+	for i, r := range rules {
+		if r != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+}
+
+func TestParseVModuleErrors(t *testing.T) {
+	for _, in := range []string{"nopattern", "=debug", "http/*=bogus"} {
+		if _, err := ParseVModule(in); err == nil {
+			t.Errorf("ParseVModule(%q): expected error, got nil", in)
+		}
+	}
+}
+
+func TestFilterAllow(t *testing.T) {
+	f, err := New(LevelWarn, "http/*=debug,db=error")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		level  Level
+		module string
+		want   bool
+	}{
+		{LevelInfo, "", false},          // below global threshold
+		{LevelWarn, "", true},           // at global threshold
+		{LevelDebug, "http/mux", true},  // vmodule lowers threshold
+		{LevelTrace, "http/mux", false}, // still below the lowered threshold
+		{LevelWarn, "db", false},        // vmodule raises threshold
+		{LevelError, "db", true},
+		{LevelInfo, "other", false}, // unmatched module falls back to global
+	}
+	for _, tt := range tests {
+		if got := f.Allow(tt.level, tt.module); got != tt.want {
+			t.Errorf("Allow(%v, %q) = %v, want %v", tt.level, tt.module, got, tt.want)
+		}
+	}
+}
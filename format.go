@@ -0,0 +1,258 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/soldiermoth/humanlog/filter"
+	"github.com/soldiermoth/humanlog/query"
+)
+
+// OutputFormat selects how a parsed log entry gets rendered.
+type OutputFormat string
+
+const (
+	// FormatTerminal renders entries as colorized, human-friendly lines.
+	// It is the default.
+	FormatTerminal OutputFormat = "terminal"
+	// FormatJSON re-emits entries as normalized, one-object-per-line
+	// JSON.
+	FormatJSON OutputFormat = "json"
+	// FormatLogfmt re-emits entries as key=value lines.
+	FormatLogfmt OutputFormat = "logfmt"
+	// FormatTemplate renders entries using HandlerOptions.Template.
+	FormatTemplate OutputFormat = "template"
+)
+
+// Entry is a single parsed log line, independent of the encoding it was
+// read in.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  map[string]string
+	// Raw holds Fields as a nested interface{} tree when the source
+	// handler preserved one (JSON does, logfmt doesn't), so --query can
+	// drill into nested objects via dotted paths.
+	Raw map[string]interface{}
+}
+
+// templateEntry is the data handed to HandlerOptions.Template; Fields is
+// exposed as a plain map so `{{range $k, $v := .Fields}}` works.
+type templateEntry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  map[string]string
+}
+
+// renderEntry filters entry's fields per opts, then renders the result
+// using opts.Format. buf/out are only used by FormatTerminal, which
+// writes through a tabwriter to keep columns aligned across entries.
+func renderEntry(buf *bytes.Buffer, out *tabwriter.Writer, opts *HandlerOptions, entry Entry, last map[string]string, skipUnchanged bool) []byte {
+	if opts.Filter != nil {
+		if lvl, ok := filter.ParseLevel(entry.Level); ok {
+			if !opts.Filter.Allow(lvl, vmoduleValue(entry, opts.VModuleKey)) {
+				return nil
+			}
+		}
+	}
+
+	if opts.Query != nil && !opts.Query.Match(query.Entry{
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+		Values:  queryValues(entry),
+	}) {
+		return nil
+	}
+
+	fields := filterFields(opts, entry.Fields, last, skipUnchanged)
+
+	switch opts.Format {
+	case FormatJSON:
+		return formatJSON(entry, fields)
+	case FormatLogfmt:
+		return formatLogfmtEntry(entry, fields)
+	case FormatTemplate:
+		return formatTemplate(opts, entry, fields)
+	case FormatTerminal, "":
+		fallthrough
+	default:
+		fields = applyValueFormatters(opts, fields)
+		var blocks []expandBlock
+		if !opts.CollapseMultilineJSON {
+			fields, blocks = splitExpand(opts, entry, fields)
+		}
+		return prettify(buf, out, opts, entry.Time, entry.Level, entry.Message, fields, blocks)
+	}
+}
+
+// expandBlock is a field pulled out of the inline key=value line to be
+// rendered as an indented block below the entry instead.
+type expandBlock struct {
+	key   string
+	lines []string
+}
+
+// splitExpand pulls fields matching opts.ExpandKeys out of fields when
+// their raw value is a multi-line string or an object/array, returning
+// the remaining inline fields plus the blocks to render below the entry.
+// JSON-sourced entries look up the raw, pre-%q value in entry.Raw;
+// logfmt entries have no Raw, but LogfmtHandler never escapes embedded
+// newlines in the first place, so entry.Fields already holds the
+// original multi-line string.
+func splitExpand(opts *HandlerOptions, entry Entry, fields map[string]string) (map[string]string, []expandBlock) {
+	if len(opts.ExpandKeys) == 0 {
+		return fields, nil
+	}
+
+	inline := make(map[string]string, len(fields))
+	var blocks []expandBlock
+	for k, v := range fields {
+		if _, expand := opts.ExpandKeys[k]; expand {
+			var raw interface{}
+			if entry.Raw != nil {
+				raw = entry.Raw[k]
+			} else {
+				raw = entry.Fields[k]
+			}
+			if lines, ok := expandLines(raw); ok {
+				blocks = append(blocks, expandBlock{key: k, lines: lines})
+				continue
+			}
+		}
+		inline[k] = v
+	}
+
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].key < blocks[j].key })
+	return inline, blocks
+}
+
+// expandLines renders raw as the lines of an expand block: a multi-line
+// string (e.g. a stack trace) is split on "\n", an object or array is
+// re-marshaled with indentation. Anything else isn't expandable.
+func expandLines(raw interface{}) ([]string, bool) {
+	switch v := raw.(type) {
+	case string:
+		if !strings.Contains(v, "\n") {
+			return nil, false
+		}
+		return strings.Split(v, "\n"), true
+	case map[string]interface{}, []interface{}:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, false
+		}
+		return strings.Split(string(b), "\n"), true
+	default:
+		return nil, false
+	}
+}
+
+// vmoduleValue returns the unquoted value of entry's vmodule key, for
+// matching against --vmodule patterns. entry.Fields flattens JSON string
+// values with %q (see JSONHandler.UnmarshalJSON), which would never
+// match a glob pattern, so JSON-sourced entries read from entry.Raw
+// instead; logfmt entries (no Raw) read the already-unquoted Fields
+// value.
+func vmoduleValue(entry Entry, key string) string {
+	if entry.Raw != nil {
+		switch v := entry.Raw[key].(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return entry.Fields[key]
+}
+
+// queryValues returns the nested interface{} tree --query expressions
+// are evaluated against, falling back to entry.Fields when the source
+// handler didn't preserve one.
+func queryValues(entry Entry) map[string]interface{} {
+	if entry.Raw != nil {
+		return entry.Raw
+	}
+	values := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		values[k] = v
+	}
+	return values
+}
+
+// formatJSON re-emits entry as a single JSON object. It prefers
+// entry.Raw over the flattened fields map so nested objects/arrays come
+// back out as nested JSON rather than being double-encoded as an
+// escaped JSON string; logfmt entries (no Raw) fall back to fields.
+func formatJSON(entry Entry, fields map[string]string) []byte {
+	out := make(map[string]interface{}, len(fields)+3)
+	for k, v := range fields {
+		if entry.Raw != nil {
+			if raw, ok := entry.Raw[k]; ok {
+				out[k] = raw
+				continue
+			}
+		}
+		out[k] = v
+	}
+	out["time"] = entry.Time.Format(time.RFC3339Nano)
+	out["level"] = entry.Level
+	out["msg"] = entry.Message
+
+	d, err := json.Marshal(out)
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"level":"error","msg":%q}`, err.Error()))
+	}
+	return d
+}
+
+func formatLogfmtEntry(entry Entry, fields map[string]string) []byte {
+	kv := make([]string, 0, len(fields)+2)
+	kv = append(kv, "time="+quoteLogfmtValue(entry.Time.Format(time.RFC3339Nano)))
+	kv = append(kv, "level="+quoteLogfmtValue(entry.Level))
+	kv = append(kv, "msg="+quoteLogfmtValue(entry.Message))
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		kv = append(kv, k+"="+quoteLogfmtValue(fields[k]))
+	}
+
+	return []byte(strings.Join(kv, " "))
+}
+
+func quoteLogfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\"=") {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+func formatTemplate(opts *HandlerOptions, entry Entry, fields map[string]string) []byte {
+	if opts.Template == nil {
+		return []byte(fmt.Sprintf("no --template given for --format=%s", FormatTemplate))
+	}
+	var buf bytes.Buffer
+	err := opts.Template.Execute(&buf, templateEntry{
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  fields,
+	})
+	if err != nil {
+		return []byte(fmt.Sprintf("template error: %v", err))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,164 @@
+package humanlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/soldiermoth/humanlog/filter"
+)
+
+// TestScannerVModuleJSON exercises --vmodule end to end through
+// JSONHandler, where Fields holds %q-quoted strings: a naive match
+// against entry.Fields[opts.VModuleKey] would never match a --vmodule
+// glob pattern.
+func TestScannerVModuleJSON(t *testing.T) {
+	f, err := filter.New(filter.LevelInfo, "http/*=debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := *DefaultOptions
+	opts.Filter = f
+	opts.VModuleKey = "logger"
+	opts.Format = FormatLogfmt
+
+	in := `{"time":"2021-01-02T15:04:05Z","level":"debug","msg":"handling request","logger":"http/server"}`
+	var out bytes.Buffer
+	if err := Scanner(strings.NewReader(in), &out, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "handling request") {
+		t.Errorf("expected debug entry from logger %q to pass --vmodule=http/*=debug, got %q", "http/server", out.String())
+	}
+}
+
+// TestScannerVModuleJSONNonStringValue makes sure vmoduleValue falls
+// back to a stringified representation for non-string vmodule-key
+// values, instead of silently treating them as empty.
+func TestScannerVModuleJSONNonStringValue(t *testing.T) {
+	f, err := filter.New(filter.LevelInfo, "123=debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := *DefaultOptions
+	opts.Filter = f
+	opts.VModuleKey = "logger"
+	opts.Format = FormatLogfmt
+
+	in := `{"time":"2021-01-02T15:04:05Z","level":"debug","msg":"handling request","logger":123}`
+	var out bytes.Buffer
+	if err := Scanner(strings.NewReader(in), &out, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "handling request") {
+		t.Errorf("expected debug entry from logger=123 to pass --vmodule=123=debug, got %q", out.String())
+	}
+}
+
+// TestScannerStructuredFormatsSkipValueFormatters makes sure
+// opts.ValueFormatters (durations, byte counts, ...) are not applied to
+// FormatJSON and FormatLogfmt, which must round-trip scalar values
+// unchanged for downstream consumers.
+func TestScannerStructuredFormatsSkipValueFormatters(t *testing.T) {
+	in := `{"time":"2021-01-02T15:04:05Z","level":"info","msg":"served","size":4508467}`
+
+	for _, format := range []OutputFormat{FormatJSON, FormatLogfmt} {
+		opts := *DefaultOptions
+		opts.Format = format
+
+		var out bytes.Buffer
+		if err := Scanner(strings.NewReader(in), &out, &opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out.String(), "4508467") {
+			t.Errorf("--format=%s: expected untouched size=4508467, got %q", format, out.String())
+		}
+		if strings.Contains(out.String(), "MiB") {
+			t.Errorf("--format=%s: ValueFormatters leaked into structured output: %q", format, out.String())
+		}
+	}
+}
+
+// TestScannerExpandLogfmt makes sure --expand pulls a multi-line field
+// out of a logfmt-sourced entry (which has no Entry.Raw) into a block
+// below the entry, rather than leaving an unreadable literal newline
+// embedded in the single-line-per-entry terminal output.
+func TestScannerExpandLogfmt(t *testing.T) {
+	opts := *DefaultOptions
+
+	in := "time=2021-01-02T15:04:05Z level=error msg=boom stack=\"main.foo\\nmain.bar\\nmain.main\""
+	var out bytes.Buffer
+	if err := Scanner(strings.NewReader(in), &out, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected the entry line, a \"stack:\" label and 3 expanded stack lines, got %d lines: %q", len(lines), out.String())
+	}
+	if !strings.Contains(lines[0], "boom") || strings.Contains(lines[0], "main.foo") {
+		t.Errorf("expected stack to be pulled out of the inline entry line, got %q", lines[0])
+	}
+	for i, want := range []string{"main.foo", "main.bar", "main.main"} {
+		if !strings.Contains(lines[i+2], want) {
+			t.Errorf("expected expanded line %d to contain %q, got %q", i+2, want, lines[i+2])
+		}
+	}
+}
+
+// TestScannerCollapseMultilineJSONLogfmt checks that
+// --collapse-multiline-json folds embedded newlines for logfmt-sourced
+// entries too, not just JSON.
+func TestScannerCollapseMultilineJSONLogfmt(t *testing.T) {
+	opts := *DefaultOptions
+	opts.CollapseMultilineJSON = true
+	opts.Truncates = false
+
+	in := "time=2021-01-02T15:04:05Z level=error msg=boom stack=\"main.foo\\nmain.bar\""
+	var out bytes.Buffer
+	if err := Scanner(strings.NewReader(in), &out, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single collapsed line, got %d lines: %q", len(lines), out.String())
+	}
+	if !strings.Contains(lines[0], "main.foo⏎main.bar") {
+		t.Errorf("expected embedded newline folded into ⏎, got %q", lines[0])
+	}
+}
+
+// TestScannerJSONRoundTripsNestedObjects makes sure --format=json
+// re-emits a nested object as a nested object, not as an escaped
+// JSON-in-JSON string.
+func TestScannerJSONRoundTripsNestedObjects(t *testing.T) {
+	opts := *DefaultOptions
+	opts.Format = FormatJSON
+
+	in := `{"time":"2021-01-02T15:04:05Z","level":"info","msg":"served","req":{"headers":{"host":"example.com"}}}`
+	var out bytes.Buffer
+	if err := Scanner(strings.NewReader(in), &out, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Req struct {
+			Headers struct {
+				Host string `json:"host"`
+			} `json:"headers"`
+		} `json:"req"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("output isn't valid JSON with a nested req.headers.host: %v\noutput: %s", err, out.String())
+	}
+	if decoded.Req.Headers.Host != "example.com" {
+		t.Errorf("expected req.headers.host=example.com, got %q (output: %s)", decoded.Req.Headers.Host, out.String())
+	}
+}
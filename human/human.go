@@ -0,0 +1,172 @@
+// Package human reformats raw field values into more readable strings:
+// durations, byte counts, large numbers and (optionally) relative
+// timestamps.
+package human
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueFormatter reformats a field's raw string value into a more human
+// friendly form. ok is false if the formatter doesn't recognize the
+// value, in which case the caller should fall back to raw unchanged.
+type ValueFormatter func(key, raw string) (formatted string, ok bool)
+
+// Defaults returns the formatters humanlog applies unless overridden:
+// Duration, Bytes and Count. RelativeTime is opt-in, since it depends on
+// wall-clock time and isn't appropriate for every field.
+func Defaults() []ValueFormatter {
+	return []ValueFormatter{Duration, Bytes, Count}
+}
+
+// unquote strips the double quotes JSONHandler wraps string values in,
+// so formatters can inspect the underlying text.
+func unquote(raw string) string {
+	if u, err := strconv.Unquote(raw); err == nil {
+		return u
+	}
+	return raw
+}
+
+var durationKeyRe = regexp.MustCompile(`(?i)(_ns|_ms|latency|duration|elapsed|took)`)
+
+// Duration renders a duration field as a compact Go duration string
+// (e.g. "1.2s"). It recognizes values that already carry a unit suffix
+// (ns, µs/us, ms, s, m, h), and bare integers under a duration-ish key
+// (took_ns, latency, ...), which are assumed to be nanoseconds.
+func Duration(key, raw string) (string, bool) {
+	v := unquote(raw)
+
+	// time.ParseDuration accepts a unitless "0"; only unit-suffixed values
+	// count here, so a bare 0 falls through to the duration-key check.
+	if d, err := time.ParseDuration(v); err == nil && strings.TrimLeft(v, "+-") != "0" {
+		return d.String(), true
+	}
+	if durationKeyRe.MatchString(key) {
+		if ns, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Duration(ns).String(), true
+		}
+	}
+	return "", false
+}
+
+var byteKeys = map[string]struct{}{
+	"bytes":          {},
+	"size":           {},
+	"content_length": {},
+	"content-length": {},
+}
+
+// Bytes renders a byte-count field (bytes, size, content_length) using
+// IEC units (KiB, MiB, ...).
+func Bytes(key, raw string) (string, bool) {
+	if _, ok := byteKeys[strings.ToLower(key)]; !ok {
+		return "", false
+	}
+	n, err := strconv.ParseFloat(unquote(raw), 64)
+	if err != nil || n < 0 {
+		return "", false
+	}
+	return formatIEC(n), true
+}
+
+func formatIEC(n float64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	const units = "KMGTPE"
+	v, exp := n, -1
+	for v >= 1024 && exp < len(units)-1 {
+		v /= 1024
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", v, units[exp])
+}
+
+var countKeyRe = regexp.MustCompile(`(?i)(count|total|requests|hits)$`)
+
+// Count renders a large count field (anything matching countKeyRe) in
+// compact form, e.g. "1.2M".
+func Count(key, raw string) (string, bool) {
+	if !countKeyRe.MatchString(key) {
+		return "", false
+	}
+	n, err := strconv.ParseFloat(unquote(raw), 64)
+	if err != nil || n < 1000 {
+		return "", false
+	}
+	return formatCount(n), true
+}
+
+func formatCount(n float64) string {
+	scales := []struct {
+		div    float64
+		suffix string
+	}{
+		{1e9, "B"},
+		{1e6, "M"},
+		{1e3, "K"},
+	}
+	for _, s := range scales {
+		if n >= s.div {
+			str := strconv.FormatFloat(n/s.div, 'f', 1, 64)
+			return strings.TrimSuffix(str, ".0") + s.suffix
+		}
+	}
+	return strconv.FormatFloat(n, 'f', 0, 64)
+}
+
+// Relative renders d as a short, human-friendly relative time, e.g.
+// "3m ago" or "in 2h".
+func Relative(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var s string
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		s = fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+// RelativeTimeAt reformats raw as a relative time string measured
+// against now, if raw parses as an RFC3339 timestamp.
+func RelativeTimeAt(raw string, now time.Time) (string, bool) {
+	v := unquote(raw)
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return "", false
+		}
+	}
+	return Relative(now.Sub(t)), true
+}
+
+// RelativeTime returns a ValueFormatter that renders RFC3339 timestamp
+// values as relative time (e.g. "3m ago"), measured against time.Now()
+// at the moment each value is formatted.
+func RelativeTime() ValueFormatter {
+	return func(key, raw string) (string, bool) {
+		return RelativeTimeAt(raw, time.Now())
+	}
+}
@@ -0,0 +1,85 @@
+package human
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		key, raw string
+		want     string
+		ok       bool
+	}{
+		{"latency", "1200000000", "1.2s", true},
+		{"took_ns", "500000", "500µs", true},
+		{"duration", `"250ms"`, "250ms", true},
+		{"msg", "hello", "", false},
+		{"count", "42", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Duration(tt.key, tt.raw)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Duration(%q, %q) = %q, %v; want %q, %v", tt.key, tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		key, raw string
+		want     string
+		ok       bool
+	}{
+		{"bytes", "500", "500 B", true},
+		{"size", "4508467", "4.3 MiB", true},
+		{"content_length", "1024", "1.0 KiB", true},
+		{"msg", "1024", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Bytes(tt.key, tt.raw)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Bytes(%q, %q) = %q, %v; want %q, %v", tt.key, tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		key, raw string
+		want     string
+		ok       bool
+	}{
+		{"request_count", "1200000", "1.2M", true},
+		{"hits", "42", "", false},
+		{"total", "1500", "1.5K", true},
+		{"msg", "1500", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Count(tt.key, tt.raw)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Count(%q, %q) = %q, %v; want %q, %v", tt.key, tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRelativeTimeAt(t *testing.T) {
+	now := time.Date(2026, 7, 27, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{now.Add(-3 * time.Minute).Format(time.RFC3339), "3m ago", true},
+		{now.Add(2 * time.Hour).Format(time.RFC3339), "in 2h", true},
+		{`"` + now.Add(-90*time.Second).Format(time.RFC3339) + `"`, "1m ago", true},
+		{"not a timestamp", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := RelativeTimeAt(tt.raw, now)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("RelativeTimeAt(%q, now) = %q, %v; want %q, %v", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package humanlog
+
+import (
+	"bufio"
+	"io"
+	"text/template"
+
+	"github.com/soldiermoth/humanlog/filter"
+	"github.com/soldiermoth/humanlog/human"
+	"github.com/soldiermoth/humanlog/query"
+)
+
+// RGB is a terminal color, expressed as 8-bit red, green and blue
+// components.
+type RGB struct {
+	R, G, B uint8
+}
+
+// Handler can recognize its log format and prettify it in a human
+// friendly fashion.
+type Handler interface {
+	// TryHandle tells if this line was handled by this handler, and if
+	// so, sets the handler's fields.
+	TryHandle(line []byte) bool
+	// Prettify the output in a human friendly fashion. Returns nil if
+	// the line should be skipped entirely (eg. because it was filtered
+	// out).
+	Prettify(skipUnchanged bool) []byte
+}
+
+// HandlerOptions customizes the behaviour of a Handler.
+type HandlerOptions struct {
+	Skip map[string]struct{}
+	Keep map[string]struct{}
+
+	SortLongest   bool
+	SkipUnchanged bool
+	Truncates     bool
+
+	LightBg bool
+
+	TimeFormat     string
+	TruncateLength int
+
+	KeyRGB RGB
+	ValRGB RGB
+
+	// Format selects how entries are rendered. It defaults to
+	// FormatTerminal.
+	Format OutputFormat
+	// Template is used to render entries when Format is FormatTemplate.
+	Template *template.Template
+
+	// Filter, when set, drops entries below its verbosity threshold (or
+	// a --vmodule override) before they are rendered.
+	Filter *filter.Filter
+	// VModuleKey is the field Filter's per-module rules are matched
+	// against, e.g. "logger" or "module".
+	VModuleKey string
+
+	// Query, when set, drops entries that don't match it before they
+	// are rendered. Build one with Compile.
+	Query Predicate
+
+	// ValueFormatters reformat field values (durations, byte counts,
+	// ...) before they're rendered. The first formatter that recognizes
+	// a value wins; unrecognized values are shown unchanged.
+	ValueFormatters []human.ValueFormatter
+
+	// ExpandKeys lists the fields (e.g. "stack", "error") that, when
+	// present with a multi-line string or an object/array value, are
+	// pulled out of the inline key=value line and rendered as an
+	// indented block below it. Only used by FormatTerminal.
+	ExpandKeys map[string]struct{}
+
+	// CollapseMultilineJSON folds embedded newlines in string field
+	// values into "⏎" instead of expanding them, for grep-friendly,
+	// one-line-per-entry output. It takes precedence over ExpandKeys.
+	CollapseMultilineJSON bool
+}
+
+// Predicate evaluates a compiled --query/-q expression against a parsed
+// entry.
+type Predicate = query.Predicate
+
+// Compile parses a query expression such as
+// `level=="error" && status>=500 && msg~="timeout"` into a Predicate
+// that Scanner uses to drop non-matching entries.
+func Compile(expr string) (Predicate, error) {
+	return query.Compile(expr)
+}
+
+// DefaultOptions used by handlers.
+var DefaultOptions = &HandlerOptions{
+	SortLongest:     true,
+	SkipUnchanged:   true,
+	Truncates:       true,
+	TimeFormat:      "Jan _2 15:04:05.000",
+	TruncateLength:  15,
+	KeyRGB:          RGB{99, 99, 99},
+	ValRGB:          RGB{188, 188, 188},
+	Format:          FormatTerminal,
+	VModuleKey:      "logger",
+	ValueFormatters: human.Defaults(),
+	ExpandKeys:      defaultExpandKeys(),
+}
+
+func defaultExpandKeys() map[string]struct{} {
+	return map[string]struct{}{
+		"stack":      {},
+		"stacktrace": {},
+		"error":      {},
+	}
+}
+
+// SetSkip sets the keys that should be skipped when printing a log
+// entry. It is mutually exclusive with SetKeep.
+func (h *HandlerOptions) SetSkip(skip []string) {
+	h.Skip = make(map[string]struct{}, len(skip))
+	for _, k := range skip {
+		h.Skip[k] = struct{}{}
+	}
+}
+
+// SetKeep sets the keys that should be the only ones printed when
+// printing a log entry. It is mutually exclusive with SetSkip.
+func (h *HandlerOptions) SetKeep(keep []string) {
+	h.Keep = make(map[string]struct{}, len(keep))
+	for _, k := range keep {
+		h.Keep[k] = struct{}{}
+	}
+}
+
+// SetExpand sets the keys whose multi-line or object values are rendered
+// as an indented block below the entry, instead of inline.
+func (h *HandlerOptions) SetExpand(keys []string) {
+	h.ExpandKeys = make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		h.ExpandKeys[k] = struct{}{}
+	}
+}
+
+func (h *HandlerOptions) shouldShowKey(key string) bool {
+	if len(h.Keep) != 0 {
+		_, ok := h.Keep[key]
+		return ok
+	}
+	if len(h.Skip) != 0 {
+		_, ok := h.Skip[key]
+		return !ok
+	}
+	return true
+}
+
+func (h *HandlerOptions) shouldShowUnchanged(key string) bool {
+	return false
+}
+
+// Scanner reads log entries from src, line by line, and writes a
+// prettified version of each entry to out using the first handler that
+// recognizes the line.
+func Scanner(src io.Reader, out io.Writer, opts *HandlerOptions) error {
+	handlers := []Handler{
+		&JSONHandler{Opts: opts},
+		&LogfmtHandler{Opts: opts},
+	}
+
+	scanner := bufio.NewScanner(src)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var handled bool
+		for _, h := range handlers {
+			if !h.TryHandle(line) {
+				continue
+			}
+			handled = true
+			if pretty := h.Prettify(opts.SkipUnchanged); pretty != nil {
+				if _, err := out.Write(pretty); err != nil {
+					return err
+				}
+				if _, err := out.Write([]byte("\n")); err != nil {
+					return err
+				}
+			}
+			break
+		}
+
+		if !handled {
+			if _, err := out.Write(line); err != nil {
+				return err
+			}
+			if _, err := out.Write([]byte("\n")); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+func imin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type byLongest []string
+
+func (b byLongest) Len() int      { return len(b) }
+func (b byLongest) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byLongest) Less(i, j int) bool {
+	return len(b[i]) > len(b[j])
+}
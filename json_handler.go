@@ -5,12 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
-
-	"github.com/aybabtme/rgbterm"
 )
 
 // JSONHandler can handle logs emmited by logrus.TextFormatter loggers.
@@ -25,6 +22,10 @@ type JSONHandler struct {
 	Time    time.Time
 	Message string
 	Fields  map[string]string
+	// Raw holds the fields as the original interface{} tree produced by
+	// json.Unmarshal, so nested objects survive for --query to drill
+	// into (Fields flattens everything to strings for display).
+	Raw map[string]interface{}
 
 	last map[string]string
 }
@@ -35,6 +36,7 @@ func (h *JSONHandler) clear() {
 	h.Message = ""
 	h.last = h.Fields
 	h.Fields = make(map[string]string)
+	h.Raw = nil
 	if h.buf != nil {
 		h.buf.Reset()
 	}
@@ -113,16 +115,25 @@ func (h *JSONHandler) UnmarshalJSON(data []byte) error {
 				h.Fields[key] = fmt.Sprintf("%g", v)
 			}
 		case string:
+			if h.Opts != nil && h.Opts.CollapseMultilineJSON && strings.Contains(v, "\n") {
+				v = strings.ReplaceAll(v, "\n", "⏎")
+			}
 			h.Fields[key] = fmt.Sprintf("%q", v)
 		default:
-			h.Fields[key] = fmt.Sprintf("%v", v)
+			if b, err := json.Marshal(v); err == nil {
+				h.Fields[key] = string(b)
+			} else {
+				h.Fields[key] = fmt.Sprintf("%v", v)
+			}
 		}
 	}
+	h.Raw = raw
 
 	return nil
 }
 
-// Prettify the output in a logrus like fashion.
+// Prettify renders the entry using h.Opts.Format (a logrus like ANSI
+// layout, by default).
 func (h *JSONHandler) Prettify(skipUnchanged bool) []byte {
 	defer h.clear()
 	if h.out == nil {
@@ -133,75 +144,6 @@ func (h *JSONHandler) Prettify(skipUnchanged bool) []byte {
 		h.out = tabwriter.NewWriter(h.buf, 0, 1, 0, '\t', 0)
 	}
 
-	var msg string
-	if h.Message == "" {
-		msg = rgbterm.FgString("<no msg>", 190, 190, 190)
-	} else if h.Opts.LightBg {
-		msg = rgbterm.FgString(h.Message, 0, 0, 0)
-	} else {
-		msg = rgbterm.FgString(h.Message, 255, 255, 255)
-	}
-
-	lvl := strings.ToUpper(h.Level)[:imin(4, len(h.Level))]
-	var level string
-	switch h.Level {
-	case "debug":
-		level = rgbterm.FgString(lvl, 221, 28, 119)
-	case "info":
-		level = rgbterm.FgString(lvl, 20, 172, 190)
-	case "warn", "warning":
-		level = rgbterm.FgString(lvl, 255, 245, 32)
-	case "error":
-		level = rgbterm.FgString(lvl, 255, 0, 0)
-	case "fatal", "panic":
-		level = rgbterm.BgString(lvl, 255, 0, 0)
-	default:
-		level = rgbterm.FgString(lvl, 221, 28, 119)
-	}
-
-	_, _ = fmt.Fprintf(h.out, "%s |%s| %s\t %s",
-		rgbterm.FgString(h.Time.Format(h.Opts.TimeFormat), 99, 99, 99),
-		level,
-		msg,
-		strings.Join(h.joinKVs(skipUnchanged, "="), "\t "),
-	)
-
-	_ = h.out.Flush()
-
-	return h.buf.Bytes()
-}
-
-func (h *JSONHandler) joinKVs(skipUnchanged bool, sep string) []string {
-
-	kv := make([]string, 0, len(h.Fields))
-	for k, v := range h.Fields {
-		if !h.Opts.shouldShowKey(k) {
-			continue
-		}
-
-		if skipUnchanged {
-			if lastV, ok := h.last[k]; ok && lastV == v && !h.Opts.shouldShowUnchanged(k) {
-				continue
-			}
-		}
-
-		kstr := rgbterm.FgString(k, h.Opts.KeyRGB.R, h.Opts.KeyRGB.G, h.Opts.KeyRGB.B)
-
-		var vstr string
-		if h.Opts.Truncates && len(v) > h.Opts.TruncateLength {
-			vstr = v[:h.Opts.TruncateLength] + "..."
-		} else {
-			vstr = v
-		}
-		vstr = rgbterm.FgString(vstr, h.Opts.ValRGB.R, h.Opts.ValRGB.G, h.Opts.ValRGB.B)
-		kv = append(kv, kstr+sep+vstr)
-	}
-
-	sort.Strings(kv)
-
-	if h.Opts.SortLongest {
-		sort.Stable(byLongest(kv))
-	}
-
-	return kv
+	entry := Entry{Time: h.Time, Level: h.Level, Message: h.Message, Fields: h.Fields, Raw: h.Raw}
+	return renderEntry(h.buf, h.out, h.Opts, entry, h.last, skipUnchanged)
 }
@@ -0,0 +1,225 @@
+package humanlog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// LogfmtHandler can handle logs emitted in the key=value style used by Go
+// kit's log package, hclog, and many other libraries.
+type LogfmtHandler struct {
+	buf *bytes.Buffer
+	out *tabwriter.Writer
+
+	Opts *HandlerOptions
+
+	Level   string
+	Time    time.Time
+	Message string
+	Fields  map[string]string
+
+	last map[string]string
+}
+
+func (h *LogfmtHandler) clear() {
+	h.Level = ""
+	h.Time = time.Time{}
+	h.Message = ""
+	h.last = h.Fields
+	h.Fields = make(map[string]string)
+	if h.buf != nil {
+		h.buf.Reset()
+	}
+}
+
+// TryHandle tells if this line was handled by this handler.
+func (h *LogfmtHandler) TryHandle(d []byte) bool {
+	if !bytes.Contains(d, []byte("=")) {
+		return false
+	}
+	err := h.UnmarshalLogfmt(d)
+	if err != nil {
+		h.clear()
+		return false
+	}
+	return true
+}
+
+// UnmarshalLogfmt sets the fields of the handler from a key=value line.
+func (h *LogfmtHandler) UnmarshalLogfmt(data []byte) error {
+	raw, err := splitLogfmt(data)
+	if err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("no key=value pairs found")
+	}
+
+	timeStr, ok := raw["time"]
+	if ok {
+		delete(raw, "time")
+	} else {
+		timeStr, ok = raw["ts"]
+		if ok {
+			delete(raw, "ts")
+		}
+	}
+	if ok {
+		h.Time, ok = tryParseTime(timeStr)
+		if !ok {
+			return fmt.Errorf("field time is not a known timestamp: %v", timeStr)
+		}
+	}
+
+	if h.Message, ok = raw["msg"]; ok {
+		delete(raw, "msg")
+	} else if h.Message, ok = raw["message"]; ok {
+		delete(raw, "message")
+	}
+
+	if h.Level, ok = raw["level"]; ok {
+		delete(raw, "level")
+	} else if h.Level, ok = raw["lvl"]; ok {
+		delete(raw, "lvl")
+	} else {
+		h.Level = "????"
+	}
+
+	if h.Fields == nil {
+		h.Fields = make(map[string]string)
+	}
+	for key, val := range raw {
+		if h.Opts != nil && h.Opts.CollapseMultilineJSON && strings.Contains(val, "\n") {
+			val = strings.ReplaceAll(val, "\n", "⏎")
+		}
+		h.Fields[key] = val
+	}
+
+	return nil
+}
+
+// Prettify renders the entry using h.Opts.Format (a logrus like ANSI
+// layout, by default).
+func (h *LogfmtHandler) Prettify(skipUnchanged bool) []byte {
+	defer h.clear()
+	if h.out == nil {
+		if h.Opts == nil {
+			h.Opts = DefaultOptions
+		}
+		h.buf = bytes.NewBuffer(nil)
+		h.out = tabwriter.NewWriter(h.buf, 0, 1, 0, '\t', 0)
+	}
+
+	entry := Entry{Time: h.Time, Level: h.Level, Message: h.Message, Fields: h.Fields}
+	return renderEntry(h.buf, h.out, h.Opts, entry, h.last, skipUnchanged)
+}
+
+// splitLogfmt parses a line of space separated key=value pairs, where
+// value may be double-quoted (with backslash escapes) to contain spaces
+// or equal signs.
+func splitLogfmt(d []byte) (map[string]string, error) {
+	out := make(map[string]string)
+	s := string(d)
+
+	for len(s) > 0 {
+		s = trimLeftSpace(s)
+		if len(s) == 0 {
+			break
+		}
+
+		eq := indexByte(s, '=')
+		if eq < 0 {
+			// no more pairs, ignore the trailing garbage
+			break
+		}
+		key := s[:eq]
+		rest := s[eq+1:]
+
+		var val string
+		if len(rest) > 0 && rest[0] == '"' {
+			v, n, err := scanQuoted(rest)
+			if err != nil {
+				// fall back to reading up to the next space
+				sp := indexByte(rest, ' ')
+				if sp < 0 {
+					sp = len(rest)
+				}
+				val = rest[:sp]
+				rest = rest[sp:]
+			} else {
+				val = v
+				rest = rest[n:]
+			}
+		} else {
+			sp := indexByte(rest, ' ')
+			if sp < 0 {
+				sp = len(rest)
+			}
+			val = rest[:sp]
+			rest = rest[sp:]
+		}
+
+		if key != "" {
+			out[key] = val
+		}
+		s = rest
+	}
+
+	return out, nil
+}
+
+// scanQuoted reads a double-quoted, backslash-escaped string starting at
+// s[0] (which must be `"`), returning its unquoted value and the number
+// of bytes consumed, including both quotes.
+func scanQuoted(s string) (val string, n int, err error) {
+	if len(s) == 0 || s[0] != '"' {
+		return "", 0, fmt.Errorf("not a quoted string")
+	}
+	var buf bytes.Buffer
+	i := 1
+	for i < len(s) {
+		switch c := s[i]; c {
+		case '"':
+			return buf.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated escape")
+			}
+			switch esc := s[i+1]; esc {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case '"', '\\':
+				buf.WriteByte(esc)
+			default:
+				buf.WriteByte(esc)
+			}
+			i += 2
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+func trimLeftSpace(s string) string {
+	i := 0
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return s[i:]
+}
+
+func indexByte(s string, b byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,133 @@
+package humanlog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aybabtme/rgbterm"
+)
+
+// filterFields applies the skip/keep and skip-unchanged rules from opts.
+// It is shared by every OutputFormat, but leaves values untouched: only
+// FormatTerminal runs them through opts.ValueFormatters (see
+// applyValueFormatters), since json/logfmt/template re-emit structured
+// data for downstream consumers and must not silently rewrite scalars
+// into human-only strings.
+func filterFields(opts *HandlerOptions, fields, last map[string]string, skipUnchanged bool) map[string]string {
+	out := make(map[string]string, len(fields))
+	for k, v := range fields {
+		if !opts.shouldShowKey(k) {
+			continue
+		}
+		if skipUnchanged {
+			if lastV, ok := last[k]; ok && lastV == v && !opts.shouldShowUnchanged(k) {
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// applyValueFormatters runs fields through opts.ValueFormatters, used
+// only when rendering FormatTerminal.
+func applyValueFormatters(opts *HandlerOptions, fields map[string]string) map[string]string {
+	out := make(map[string]string, len(fields))
+	for k, v := range fields {
+		out[k] = formatValue(opts, k, v)
+	}
+	return out
+}
+
+// formatValue runs raw through opts.ValueFormatters, returning the first
+// formatted result, or raw unchanged if none of them recognize it.
+func formatValue(opts *HandlerOptions, key, raw string) string {
+	for _, f := range opts.ValueFormatters {
+		if formatted, ok := f(key, raw); ok {
+			return formatted
+		}
+	}
+	return raw
+}
+
+// prettify renders a parsed, already-filtered log entry in a logrus like
+// fashion, writing through out/buf so callers can reuse the same
+// tabwriter across calls. Any expand blocks are appended below the
+// entry, indented and dimmed.
+func prettify(buf *bytes.Buffer, out *tabwriter.Writer, opts *HandlerOptions, t time.Time, level, message string, fields map[string]string, blocks []expandBlock) []byte {
+	var msg string
+	if message == "" {
+		msg = rgbterm.FgString("<no msg>", 190, 190, 190)
+	} else if opts.LightBg {
+		msg = rgbterm.FgString(message, 0, 0, 0)
+	} else {
+		msg = rgbterm.FgString(message, 255, 255, 255)
+	}
+
+	lvl := strings.ToUpper(level)[:imin(4, len(level))]
+	var lvlStr string
+	switch level {
+	case "debug":
+		lvlStr = rgbterm.FgString(lvl, 221, 28, 119)
+	case "info":
+		lvlStr = rgbterm.FgString(lvl, 20, 172, 190)
+	case "warn", "warning":
+		lvlStr = rgbterm.FgString(lvl, 255, 245, 32)
+	case "error":
+		lvlStr = rgbterm.FgString(lvl, 255, 0, 0)
+	case "fatal", "panic":
+		lvlStr = rgbterm.BgString(lvl, 255, 0, 0)
+	default:
+		lvlStr = rgbterm.FgString(lvl, 221, 28, 119)
+	}
+
+	_, _ = fmt.Fprintf(out, "%s |%s| %s\t %s",
+		rgbterm.FgString(t.Format(opts.TimeFormat), 99, 99, 99),
+		lvlStr,
+		msg,
+		strings.Join(joinKVs(opts, fields, "="), "\t "),
+	)
+
+	_ = out.Flush()
+
+	for _, b := range blocks {
+		label := rgbterm.FgString(b.key+":", opts.KeyRGB.R, opts.KeyRGB.G, opts.KeyRGB.B)
+		buf.WriteString("\n  " + label)
+		for _, line := range b.lines {
+			buf.WriteString("\n    " + rgbterm.FgString(line, opts.ValRGB.R, opts.ValRGB.G, opts.ValRGB.B))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// joinKVs renders already-filtered fields as "key=value" pairs, applying
+// the truncation and sorting rules from opts.
+func joinKVs(opts *HandlerOptions, fields map[string]string, sep string) []string {
+
+	kv := make([]string, 0, len(fields))
+	for k, v := range fields {
+		kstr := rgbterm.FgString(k, opts.KeyRGB.R, opts.KeyRGB.G, opts.KeyRGB.B)
+
+		var vstr string
+		if opts.Truncates && len(v) > opts.TruncateLength {
+			vstr = v[:opts.TruncateLength] + "..."
+		} else {
+			vstr = v
+		}
+		vstr = rgbterm.FgString(vstr, opts.ValRGB.R, opts.ValRGB.G, opts.ValRGB.B)
+		kv = append(kv, kstr+sep+vstr)
+	}
+
+	sort.Strings(kv)
+
+	if opts.SortLongest {
+		sort.Stable(byLongest(kv))
+	}
+
+	return kv
+}
@@ -0,0 +1,127 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+type andNode struct{ l, r Predicate }
+
+func (n andNode) Match(e Entry) bool { return n.l.Match(e) && n.r.Match(e) }
+
+type orNode struct{ l, r Predicate }
+
+func (n orNode) Match(e Entry) bool { return n.l.Match(e) || n.r.Match(e) }
+
+type notNode struct{ sub Predicate }
+
+func (n notNode) Match(e Entry) bool { return !n.sub.Match(e) }
+
+type cmpNode struct {
+	path []string
+	op   string
+	lit  literal
+	re   *regexp.Regexp
+}
+
+func (n cmpNode) Match(e Entry) bool {
+	val, ok := lookup(e, n.path)
+	if !ok {
+		return false
+	}
+
+	switch n.op {
+	case "~=":
+		s, ok := asString(val)
+		return ok && n.re.MatchString(s)
+	case "==", "!=":
+		eq := equalTo(val, n.lit)
+		if n.op == "!=" {
+			return !eq
+		}
+		return eq
+	default: // <, <=, >, >=
+		f, ok := asFloat(val)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "<":
+			return f < n.lit.num
+		case "<=":
+			return f <= n.lit.num
+		case ">":
+			return f > n.lit.num
+		case ">=":
+			return f >= n.lit.num
+		default:
+			return false
+		}
+	}
+}
+
+// lookup resolves a dotted field path against an entry, special-casing
+// level/msg/time (which live outside Values) before drilling into the
+// nested Values tree.
+func lookup(e Entry, path []string) (interface{}, bool) {
+	if len(path) == 1 {
+		switch path[0] {
+		case "level":
+			return e.Level, true
+		case "msg", "message":
+			return e.Message, true
+		case "time":
+			return e.Time, true
+		}
+	}
+
+	var cur interface{} = e.Values
+	for _, seg := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[seg]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func equalTo(val interface{}, lit literal) bool {
+	if lit.isString {
+		s, ok := asString(val)
+		return ok && s == lit.str
+	}
+	f, ok := asFloat(val)
+	return ok && f == lit.num
+}
+
+func asFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func asString(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return t, true
+	case fmt.Stringer:
+		return t.String(), true
+	default:
+		return fmt.Sprintf("%v", t), true
+	}
+}
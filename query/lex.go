@@ -0,0 +1,126 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize turns a query expression into a flat token stream.
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(s[i:], "&&"):
+			toks = append(toks, token{tokAnd, "&&"})
+			i += 2
+		case strings.HasPrefix(s[i:], "||"):
+			toks = append(toks, token{tokOr, "||"})
+			i += 2
+		case strings.HasPrefix(s[i:], "=="), strings.HasPrefix(s[i:], "!="),
+			strings.HasPrefix(s[i:], "<="), strings.HasPrefix(s[i:], ">="),
+			strings.HasPrefix(s[i:], "~="):
+			toks = append(toks, token{tokOp, s[i : i+2]})
+			i += 2
+		case c == '<' || c == '>':
+			toks = append(toks, token{tokOp, string(c)})
+			i++
+		case c == '!':
+			toks = append(toks, token{tokNot, "!"})
+			i++
+		case c == '(':
+			toks = append(toks, token{tokLParen, "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")"})
+			i++
+		case c == '"':
+			str, n, err := scanString(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokString, str})
+			i += n
+		case isDigit(c) || (c == '-' && i+1 < len(s) && isDigit(s[i+1])):
+			n := scanNumber(s[i:])
+			toks = append(toks, token{tokNumber, s[i : i+n]})
+			i += n
+		case isIdentStart(c):
+			n := scanIdent(s[i:])
+			toks = append(toks, token{tokIdent, s[i : i+n]})
+			i += n
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+func isDigit(c byte) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c byte) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isIdentPart(c byte) bool  { return isIdentStart(c) || isDigit(c) || c == '.' }
+
+func scanIdent(s string) int {
+	i := 0
+	for i < len(s) && isIdentPart(s[i]) {
+		i++
+	}
+	return i
+}
+
+func scanNumber(s string) int {
+	i := 0
+	if s[i] == '-' {
+		i++
+	}
+	for i < len(s) && (isDigit(s[i]) || s[i] == '.') {
+		i++
+	}
+	return i
+}
+
+// scanString reads a double-quoted, backslash-escaped string literal
+// starting at s[0] (which must be `"`), returning its unquoted value and
+// the number of bytes consumed, including both quotes.
+func scanString(s string) (val string, n int, err error) {
+	var buf strings.Builder
+	i := 1
+	for i < len(s) {
+		switch c := s[i]; c {
+		case '"':
+			return buf.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated escape in string literal")
+			}
+			buf.WriteByte(s[i+1])
+			i += 2
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string literal")
+}
@@ -0,0 +1,138 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokOr {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokAnd {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (Predicate, error) {
+	if t, ok := p.peek(); ok && t.kind == tokNot {
+		p.next()
+		sub, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{sub}, nil
+	}
+	if t, ok := p.peek(); ok && t.kind == tokLParen {
+		p.next()
+		sub, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.next(); !ok || t.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')'")
+		}
+		return sub, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Predicate, error) {
+	pathTok, ok := p.next()
+	if !ok || pathTok.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name, got %q", pathTok.text)
+	}
+	opTok, ok := p.next()
+	if !ok || opTok.kind != tokOp {
+		return nil, fmt.Errorf("expected comparison operator after %q", pathTok.text)
+	}
+	valTok, ok := p.next()
+	if !ok || (valTok.kind != tokString && valTok.kind != tokNumber) {
+		return nil, fmt.Errorf("expected value after operator %q", opTok.text)
+	}
+
+	lit := literal{isString: valTok.kind == tokString, str: valTok.text}
+	if !lit.isString {
+		f, err := strconv.ParseFloat(valTok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %v", valTok.text, err)
+		}
+		lit.num = f
+	}
+
+	var re *regexp.Regexp
+	if opTok.text == "~=" {
+		if !lit.isString {
+			return nil, fmt.Errorf("~= requires a string (regex) operand")
+		}
+		var err error
+		re, err = regexp.Compile(lit.str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", lit.str, err)
+		}
+	}
+
+	return cmpNode{
+		path: strings.Split(pathTok.text, "."),
+		op:   opTok.text,
+		lit:  lit,
+		re:   re,
+	}, nil
+}
+
+type literal struct {
+	isString bool
+	str      string
+	num      float64
+}
@@ -0,0 +1,50 @@
+// Package query implements a small jq/CEL-inspired expression language
+// for filtering log entries by their parsed fields, e.g.
+//
+//	level=="error" && status>=500 && msg~="timeout"
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// Entry is the data a compiled Predicate is evaluated against.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	// Values holds the entry's fields as a nested interface{} tree (as
+	// produced by encoding/json), so dotted paths like req.headers.host
+	// can drill into nested objects.
+	Values map[string]interface{}
+}
+
+// Predicate is a compiled query expression.
+type Predicate interface {
+	Match(e Entry) bool
+}
+
+// Compile parses expr into a Predicate.
+//
+// Supported operators are ==, !=, <, <=, >, >= and ~= (regex match),
+// combined with the boolean operators &&, || and unary !, with
+// parentheses for grouping. The left-hand side of a comparison is a
+// dotted field path (e.g. req.headers.host); the right-hand side is a
+// string or numeric literal.
+func Compile(expr string) (Predicate, error) {
+	toks, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.toks) {
+		t := p.toks[p.pos]
+		return nil, fmt.Errorf("unexpected token %q", t.text)
+	}
+	return node, nil
+}
@@ -0,0 +1,64 @@
+package query
+
+import "testing"
+
+func TestCompileAndMatch(t *testing.T) {
+	entry := Entry{
+		Level:   "error",
+		Message: "request timed out",
+		Values: map[string]interface{}{
+			"status": float64(503),
+			"req": map[string]interface{}{
+				"headers": map[string]interface{}{
+					"host": "example.com",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`level=="error"`, true},
+		{`level=="info"`, false},
+		{`level!="info"`, true},
+		{`status>=500`, true},
+		{`status>500`, true},
+		{`status<500`, false},
+		{`msg~="timed out"`, true},
+		{`msg~="^timeout"`, false},
+		{`req.headers.host=="example.com"`, true},
+		{`req.headers.host=="other.com"`, false},
+		{`level=="error" && status>=500`, true},
+		{`level=="error" && status<500`, false},
+		{`level=="info" || status>=500`, true},
+		{`!(level=="info")`, true},
+		{`missing.field=="x"`, false},
+	}
+
+	for _, tt := range tests {
+		pred, err := Compile(tt.expr)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.expr, err)
+		}
+		if got := pred.Match(entry); got != tt.want {
+			t.Errorf("Compile(%q).Match(entry) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`level==`,
+		`level=="error" &&`,
+		`level~=500`,
+		`(level=="error"`,
+		`level=="error")`,
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q): expected error, got nil", expr)
+		}
+	}
+}
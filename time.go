@@ -0,0 +1,24 @@
+package humanlog
+
+import "time"
+
+// knownTimeFormats are tried, in order, when parsing a timestamp found in
+// a log entry.
+var knownTimeFormats = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// tryParseTime attempts to parse str using a set of known timestamp
+// formats, returning ok=false if none of them match.
+func tryParseTime(str string) (time.Time, bool) {
+	for _, format := range knownTimeFormats {
+		if t, err := time.Parse(format, str); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}